@@ -0,0 +1,372 @@
+package server
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLSConfig holds TLS-related settings for Config.
+type TLSConfig struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+
+	// MinVersion is a crypto/tls version constant (e.g. tls.VersionTLS12).
+	// Zero means the crypto/tls default.
+	MinVersion uint16
+
+	// Autocert, if set, is called to obtain a *tls.Config that manages
+	// certificates automatically (e.g. via golang.org/x/crypto/acme/autocert)
+	// instead of CertFile/KeyFile.
+	Autocert func() (*tls.Config, error) `json:"-"`
+}
+
+// LoggingConfig holds logging-related settings for Config.
+type LoggingConfig struct {
+	Output string
+}
+
+// DatabaseConfig holds database connection settings for Config.
+type DatabaseConfig struct {
+	DSN string
+}
+
+// Option customizes how LoadConfig builds a Config.
+type Option func(*loadOptions)
+
+type loadOptions struct {
+	envPrefix string
+	flagSet   *flag.FlagSet
+	args      []string
+}
+
+// WithEnvPrefix sets the prefix used when reading environment variables,
+// e.g. "EMBEDM" maps Config.Host to EMBEDM_HOST and Config.TLS.CertFile
+// to EMBEDM_TLS_CERTFILE.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *loadOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithFlags registers CLI flags on fs and parses args against them,
+// giving flags the highest precedence.
+func WithFlags(fs *flag.FlagSet, args []string) Option {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+		o.args = args
+	}
+}
+
+// LoadConfig builds a Config by merging, in increasing order of
+// precedence: defaults from NewConfig, a config file at path (YAML, TOML
+// or JSON, selected by extension), environment variables, and CLI flags.
+// path may be empty to skip file loading.
+func LoadConfig(path string, opts ...Option) (Config, error) {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg := NewConfig("localhost", 8080)
+
+	if path != "" {
+		if err := loadConfigFile(path, &cfg); err != nil {
+			return Config{}, fmt.Errorf("embedm: load config file: %w", err)
+		}
+	}
+
+	applyEnv(&cfg, o.envPrefix)
+
+	if o.flagSet != nil {
+		BindFlags(o.flagSet, &cfg)
+		if err := o.flagSet.Parse(o.args); err != nil {
+			return Config{}, fmt.Errorf("embedm: parse flags: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// BindFlags registers a flag for every leaf field of Config (including
+// nested structs like TLS, Logging and Database), so embedding programs
+// (e.g. built on cobra) can compose their own flag sets with embedm's
+// configuration. Nested fields get dotted names, e.g. "tls.certfile".
+func BindFlags(fs *flag.FlagSet, cfg *Config) {
+	bindFlags(fs, reflect.ValueOf(cfg).Elem(), nil)
+}
+
+func bindFlags(fs *flag.FlagSet, v reflect.Value, path []string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if field.Kind() == reflect.Func {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), sf.Name)
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			bindFlags(fs, field, fieldPath)
+			continue
+		}
+
+		name := strings.ToLower(strings.Join(fieldPath, "."))
+		usage := fmt.Sprintf("embedm config: %s (default %v)", name, field.Interface())
+
+		// fs.Func routes through setScalar so every field kind embedm's
+		// Config can hold (including uint16, as used by TLS.MinVersion)
+		// is covered without a type-specific *Var call per kind.
+		fs.Func(name, usage, func(s string) error {
+			return setScalar(field, s)
+		})
+	}
+}
+
+// DumpConfig returns the effective merged Config as indented JSON, ready
+// to print, similar to arvados' -dump-config. It does not print anything
+// itself.
+func DumpConfig(cfg Config) string {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<error dumping config: %v>", err)
+	}
+	return string(b)
+}
+
+func loadConfigFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		table, err := parseYAML(data)
+		if err != nil {
+			return err
+		}
+		return assignTable(reflect.ValueOf(cfg).Elem(), table)
+	case ".toml":
+		table, err := parseTOML(data)
+		if err != nil {
+			return err
+		}
+		return assignTable(reflect.ValueOf(cfg).Elem(), table)
+	default:
+		return fmt.Errorf("unsupported config file extension %q", ext)
+	}
+}
+
+// parseYAML decodes a subset of YAML sufficient for embedm's Config tree:
+// scalar "key: value" pairs and one level of nested "key:" tables indented
+// below it. It does not support lists, multi-line scalars or anchors.
+func parseYAML(data []byte) (map[string]any, error) {
+	root := map[string]any{}
+	type frame struct {
+		indent int
+		table  map[string]any
+	}
+	stack := []frame{{indent: -1, table: root}}
+
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := stripComment(raw)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+
+		idx := strings.Index(trimmed, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("yaml: line %d: expected \"key: value\"", n+1)
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		val := strings.TrimSpace(trimmed[idx+1:])
+
+		for len(stack) > 1 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		current := stack[len(stack)-1].table
+
+		if val == "" {
+			nested := map[string]any{}
+			current[key] = nested
+			stack = append(stack, frame{indent: indent, table: nested})
+			continue
+		}
+		current[key] = unquote(val)
+	}
+	return root, nil
+}
+
+// parseTOML decodes a subset of TOML sufficient for embedm's Config tree:
+// top-level "key = value" pairs followed by one level of "[section]"
+// tables. It does not support arrays, inline tables or nested sections.
+func parseTOML(data []byte) (map[string]any, error) {
+	root := map[string]any{}
+	current := root
+
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			section := strings.TrimSpace(strings.Trim(line, "[]"))
+			if section == "" {
+				return nil, fmt.Errorf("toml: line %d: empty section header", n+1)
+			}
+			nested := map[string]any{}
+			root[section] = nested
+			current = nested
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("toml: line %d: expected \"key = value\"", n+1)
+		}
+		key := strings.TrimSpace(line[:idx])
+		val := strings.TrimSpace(line[idx+1:])
+		current[key] = unquote(val)
+	}
+	return root, nil
+}
+
+func stripComment(line string) string {
+	if idx := strings.Index(line, "#"); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"'`)
+}
+
+// assignTable applies a decoded YAML/TOML table onto v (a struct value),
+// matching keys to field names case-insensitively and recursing into
+// nested tables. It returns an error for any key that doesn't match a
+// field, so typos and unsupported settings aren't silently dropped.
+func assignTable(v reflect.Value, table map[string]any) error {
+	t := v.Type()
+	for key, raw := range table {
+		field, sf, ok := findField(t, v, key)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			nested, ok := raw.(map[string]any)
+			if !ok {
+				return fmt.Errorf("config key %q: expected a nested table", key)
+			}
+			if err := assignTable(field, nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("config key %q: expected a scalar value", key)
+		}
+		if err := setScalar(field, s); err != nil {
+			return fmt.Errorf("config key %q: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func findField(t reflect.Type, v reflect.Value, name string) (reflect.Value, reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if strings.EqualFold(sf.Name, name) {
+			return v.Field(i), sf, true
+		}
+	}
+	return reflect.Value{}, reflect.StructField{}, false
+}
+
+// setScalar assigns the string value s to field, converting it according
+// to field's kind (time.Duration, string, int/int64, bool, or any unsigned
+// integer kind). It's used
+// both to apply struct-tag defaults and to decode scalar config values.
+func setScalar(field reflect.Value, s string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(s)
+	case field.Kind() == reflect.Int, field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Uint, field.Kind() == reflect.Uint8,
+		field.Kind() == reflect.Uint16, field.Kind() == reflect.Uint32,
+		field.Kind() == reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field kind %s", field.Kind())
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, prefix string) {
+	applyEnvFields(reflect.ValueOf(cfg).Elem(), prefix, nil)
+}
+
+func applyEnvFields(v reflect.Value, prefix string, path []string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if field.Kind() == reflect.Func {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), sf.Name)
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			applyEnvFields(field, prefix, fieldPath)
+			continue
+		}
+
+		name := strings.ToUpper(strings.Join(fieldPath, "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		if val, ok := os.LookupEnv(name); ok {
+			_ = setScalar(field, val)
+		}
+	}
+}
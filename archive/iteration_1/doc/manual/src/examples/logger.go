@@ -0,0 +1,85 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is a structured, leveled logger used by the Server and its
+// middleware. The default implementation is backed by log/slog.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// NewLogger returns the default Logger implementation, configured from
+// cfg.LogLevel and cfg.LogFormat.
+func NewLogger(cfg Config) Logger {
+	return &slogLogger{l: cfg.Logger()}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s *slogLogger) Debug(msg string, kv ...any) { s.l.Debug(msg, kv...) }
+func (s *slogLogger) Info(msg string, kv ...any)  { s.l.Info(msg, kv...) }
+func (s *slogLogger) Warn(msg string, kv ...any)  { s.l.Warn(msg, kv...) }
+func (s *slogLogger) Error(msg string, kv ...any) { s.l.Error(msg, kv...) }
+
+// Logger builds a *slog.Logger from c, writing to c.Logging.Output (or
+// stdout if unset) in c.LogFormat ("json" or the default "text") at
+// c.LogLevel. For backwards compatibility, Verbose=true implies debug
+// level when LogLevel is unset.
+func (c Config) Logger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: c.logLevel()}
+
+	var handler slog.Handler
+	if strings.EqualFold(c.LogFormat, "json") {
+		handler = slog.NewJSONHandler(c.logOutput(), opts)
+	} else {
+		handler = slog.NewTextHandler(c.logOutput(), opts)
+	}
+	return slog.New(handler)
+}
+
+// logOutput resolves c.Logging.Output to a writer: "" and "stdout" mean
+// os.Stdout, "stderr" means os.Stderr, and anything else is treated as a
+// file path to append to. A file that can't be opened falls back to
+// os.Stdout rather than failing Logger's error-free signature.
+func (c Config) logOutput() io.Writer {
+	switch strings.ToLower(strings.TrimSpace(c.Logging.Output)) {
+	case "", "stdout":
+		return os.Stdout
+	case "stderr":
+		return os.Stderr
+	default:
+		f, err := os.OpenFile(c.Logging.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return os.Stdout
+		}
+		return f
+	}
+}
+
+func (c Config) logLevel() slog.Level {
+	switch strings.ToLower(c.LogLevel) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error", "fatal":
+		return slog.LevelError
+	case "", "info":
+		if c.LogLevel == "" && c.Verbose {
+			return slog.LevelDebug
+		}
+		return slog.LevelInfo
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Middleware wraps a Handler to produce a new Handler, e.g. to add
+// logging, recovery, or timeouts around the call.
+type Middleware func(Handler) Handler
+
+// Chain composes a series of Middleware in order, outermost first.
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain builds a Chain from the given middlewares, applied in the
+// order they're listed (the first middleware runs first).
+func NewChain(mw ...Middleware) Chain {
+	return Chain{middlewares: mw}
+}
+
+// Then wraps final with every middleware in the chain and returns the
+// resulting Handler.
+func (c Chain) Then(final Handler) Handler {
+	h := final
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// LoggingMiddleware logs each request's path and the handler's result
+// when verbose is true, and logs nothing when it's false; pass
+// Config.Verbose to respect the configured level. For structured,
+// leveled logging use StructuredLoggingMiddleware instead.
+func LoggingMiddleware(verbose bool) Middleware {
+	return func(next Handler) Handler {
+		return &loggingHandler{verbose: verbose, next: next}
+	}
+}
+
+type loggingHandler struct {
+	verbose bool
+	next    Handler
+}
+
+func (h *loggingHandler) ServeHTTP(ctx context.Context, path string) string {
+	if h.verbose {
+		log.Printf("server: request path=%q", path)
+	}
+	result := h.next.ServeHTTP(ctx, path)
+	if h.verbose {
+		log.Printf("server: response path=%q result=%q", path, result)
+	}
+	return result
+}
+
+func (h *loggingHandler) Middleware(next Handler) Handler {
+	return LoggingMiddleware(h.verbose)(next)
+}
+
+// StructuredLoggingMiddleware is like LoggingMiddleware but logs through
+// a Logger with structured key/value pairs instead of the standard
+// log package.
+func StructuredLoggingMiddleware(logger Logger) Middleware {
+	return func(next Handler) Handler {
+		return &structuredLoggingHandler{logger: logger, next: next}
+	}
+}
+
+type structuredLoggingHandler struct {
+	logger Logger
+	next   Handler
+}
+
+func (h *structuredLoggingHandler) ServeHTTP(ctx context.Context, path string) string {
+	h.logger.Debug("request", "path", path)
+	result := h.next.ServeHTTP(ctx, path)
+	h.logger.Debug("response", "path", path, "result", result)
+	return result
+}
+
+func (h *structuredLoggingHandler) Middleware(next Handler) Handler {
+	return StructuredLoggingMiddleware(h.logger)(next)
+}
+
+// RecoverMiddleware catches panics from downstream ServeHTTP calls and
+// turns them into an error result instead of crashing the server.
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return &recoverHandler{next: next}
+	}
+}
+
+type recoverHandler struct {
+	next Handler
+}
+
+func (h *recoverHandler) ServeHTTP(ctx context.Context, path string) (result string) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = fmt.Sprintf("panic recovered: %v", r)
+		}
+	}()
+	return h.next.ServeHTTP(ctx, path)
+}
+
+func (h *recoverHandler) Middleware(next Handler) Handler {
+	return RecoverMiddleware()(next)
+}
+
+// TimeoutMiddleware cancels the request context after d and returns early
+// if the downstream handler hasn't responded by then.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return &timeoutHandler{d: d, next: next}
+	}
+}
+
+type timeoutHandler struct {
+	d    time.Duration
+	next Handler
+}
+
+func (h *timeoutHandler) ServeHTTP(ctx context.Context, path string) string {
+	ctx, cancel := context.WithTimeout(ctx, h.d)
+	defer cancel()
+
+	result := make(chan string, 1)
+	go func() { result <- h.next.ServeHTTP(ctx, path) }()
+
+	select {
+	case res := <-result:
+		return res
+	case <-ctx.Done():
+		return fmt.Sprintf("request timed out after %s", h.d)
+	}
+}
+
+func (h *timeoutHandler) Middleware(next Handler) Handler {
+	return TimeoutMiddleware(h.d)(next)
+}
+
+// RequestIDMiddleware stamps each request's context with a unique
+// request ID, retrievable with RequestIDFromContext.
+func RequestIDMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return &requestIDHandler{next: next}
+	}
+}
+
+type requestIDHandler struct {
+	next Handler
+}
+
+type requestIDKey struct{}
+
+var requestIDCounter uint64
+
+func (h *requestIDHandler) ServeHTTP(ctx context.Context, path string) string {
+	id := atomic.AddUint64(&requestIDCounter, 1)
+	ctx = context.WithValue(ctx, requestIDKey{}, fmt.Sprintf("req-%d", id))
+	return h.next.ServeHTTP(ctx, path)
+}
+
+func (h *requestIDHandler) Middleware(next Handler) Handler {
+	return RequestIDMiddleware()(next)
+}
+
+// RequestIDFromContext returns the request ID stamped by
+// RequestIDMiddleware, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Server runs a Handler behind net/http, applying the timeouts and TLS
+// settings from Config.
+type Server struct {
+	cfg    Config
+	logger Logger
+	srv    *http.Server
+}
+
+// New creates a Server that serves h according to cfg.
+func New(cfg Config, h Handler) *Server {
+	return &Server{
+		cfg:    cfg,
+		logger: NewLogger(cfg),
+		srv: &http.Server{
+			Addr:           cfg.Address(),
+			Handler:        adaptHandler(h),
+			ReadTimeout:    cfg.ReadTimeout,
+			WriteTimeout:   cfg.WriteTimeout,
+			IdleTimeout:    cfg.IdleTimeout,
+			MaxHeaderBytes: cfg.MaxHeaderBytes,
+		},
+	}
+}
+
+// adaptHandler bridges embedm's path-based Handler to net/http.
+func adaptHandler(h Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := h.ServeHTTP(r.Context(), r.URL.Path)
+		_, _ = w.Write([]byte(result))
+	}
+}
+
+// ListenAndServe starts the server on cfg.Address().
+func (s *Server) ListenAndServe() error {
+	return s.srv.ListenAndServe()
+}
+
+// ListenAndServeTLS starts the server using the given cert/key pair, or
+// cfg.TLS.Autocert if set and certFile/keyFile are empty.
+func (s *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	if s.cfg.TLS.Autocert != nil && certFile == "" && keyFile == "" {
+		tlsCfg, err := s.cfg.TLS.Autocert()
+		if err != nil {
+			return err
+		}
+		s.srv.TLSConfig = tlsCfg
+		return s.srv.ListenAndServeTLS("", "")
+	}
+
+	if s.srv.TLSConfig == nil {
+		s.srv.TLSConfig = &tls.Config{MinVersion: s.cfg.TLS.MinVersion}
+	}
+	return s.srv.ListenAndServeTLS(certFile, keyFile)
+}
+
+// Shutdown gracefully shuts down the server, waiting for in-flight
+// requests to finish or ctx to be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// Run starts the server (TLS if cfg.TLS.Enabled, otherwise plain HTTP)
+// and blocks until ctx is canceled or a SIGINT/SIGTERM is received, at
+// which point it shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		if s.cfg.TLS.Enabled {
+			errCh <- s.ListenAndServeTLS(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
+		} else {
+			errCh <- s.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		s.logger.Error("server error", "err", err)
+		return err
+	case <-ctx.Done():
+		s.logger.Info("shutting down")
+		return s.Shutdown(context.Background())
+	}
+}
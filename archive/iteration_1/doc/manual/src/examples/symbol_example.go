@@ -1,17 +1,39 @@
 package server
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
-// Config holds server configuration.
+// Config holds server configuration. Fields tagged `default:"..."` are
+// populated by applyDefaults when the field is still at its zero value.
 type Config struct {
-	Host    string
-	Port    int
+	Host    string `default:"localhost"`
+	Port    int    `default:"8080"`
 	Verbose bool
+
+	// LogLevel is one of debug, info, warn, error, fatal. Left untagged
+	// (rather than defaulted to "info") so Verbose=true keeps implying
+	// debug level even after applyDefaults runs; see Config.logLevel.
+	LogLevel string
+	// LogFormat is "text" or "json". Defaults to text.
+	LogFormat string `default:"text"`
+
+	ReadTimeout    time.Duration `default:"30s"`
+	WriteTimeout   time.Duration `default:"30s"`
+	IdleTimeout    time.Duration `default:"120s"`
+	MaxHeaderBytes int           `default:"1048576"`
+
+	TLS      TLSConfig
+	Logging  LoggingConfig
+	Database DatabaseConfig
 }
 
-// Handler defines the request handling interface.
+// Handler defines the request handling interface. ServeHTTP takes a
+// context so timeouts and cancellation propagate through middleware.
 type Handler interface {
-	ServeHTTP(path string) string
+	ServeHTTP(ctx context.Context, path string) string
 	Middleware(next Handler) Handler
 }
 
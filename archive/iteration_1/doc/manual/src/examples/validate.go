@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Validate checks cfg for obvious misconfiguration, returning every
+// problem found (via errors.Join) rather than just the first.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Host == "" {
+		errs = append(errs, fmt.Errorf("host must not be empty"))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.TLS.Enabled && c.TLS.Autocert == nil {
+		if c.TLS.CertFile == "" {
+			errs = append(errs, fmt.Errorf("tls.certFile is required when tls is enabled"))
+		}
+		if c.TLS.KeyFile == "" {
+			errs = append(errs, fmt.Errorf("tls.keyFile is required when tls is enabled"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// durationType is used to special-case time.Duration fields, which are
+// int64 under the hood but whose default tags (and config values) are
+// duration strings ("30s") rather than integers.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyDefaults walks v's fields (v must be addressable), setting any
+// zero-valued field tagged `default:"..."` to its tag value. Nested
+// structs are walked recursively.
+func applyDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			if err := applyDefaults(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("default")
+		if !ok || !field.IsZero() {
+			continue
+		}
+
+		if err := setScalar(field, tag); err != nil {
+			return fmt.Errorf("default for %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// Load is LoadConfig followed by default-filling and validation: it's
+// the entry point most programs should use to build a Config.
+func Load(path string, opts ...Option) (Config, error) {
+	cfg, err := LoadConfig(path, opts...)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := applyDefaults(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return Config{}, fmt.Errorf("embedm: apply defaults: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("embedm: invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// MustLoad is like Load but panics on error, for use in program
+// initialization where a bad config should abort startup immediately.
+func MustLoad(path string, opts ...Option) Config {
+	cfg, err := Load(path, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// GenerateConfigFile writes a fully-populated template config file to
+// path in the given format ("json", "yaml", "toml"), built from the zero
+// Config plus its struct-tag defaults, covering every field (including
+// nested TLS/Logging/Database tables) so operators can see every knob.
+func GenerateConfigFile(path, format string) error {
+	var cfg Config
+	if err := applyDefaults(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return fmt.Errorf("embedm: apply defaults: %w", err)
+	}
+
+	var data []byte
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return fmt.Errorf("embedm: marshal template: %w", err)
+		}
+		data = b
+	case "yaml":
+		data = []byte(yamlTemplate(reflect.ValueOf(cfg), 0))
+	case "toml":
+		data = []byte(tomlTemplate(reflect.ValueOf(cfg)))
+	default:
+		return fmt.Errorf("unsupported config format %q", format)
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// yamlTemplate renders v (a struct) as indented "key: value" YAML,
+// recursing into nested struct fields (mirroring parseYAML's shape).
+func yamlTemplate(v reflect.Value, indent int) string {
+	var sb strings.Builder
+	pad := strings.Repeat("  ", indent)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if field.Kind() == reflect.Func {
+			continue
+		}
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			sb.WriteString(fmt.Sprintf("%s%s:\n", pad, sf.Name))
+			sb.WriteString(yamlTemplate(field, indent+1))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s%s: %s\n", pad, sf.Name, formatScalar(field)))
+	}
+	return sb.String()
+}
+
+// tomlTemplate renders v (a struct) as TOML: top-level scalars first,
+// followed by a "[Name]" section per nested struct field (mirroring
+// parseTOML's shape).
+func tomlTemplate(v reflect.Value) string {
+	var scalars, sections strings.Builder
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if field.Kind() == reflect.Func {
+			continue
+		}
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			sections.WriteString(fmt.Sprintf("\n[%s]\n", sf.Name))
+			ft := field.Type()
+			for j := 0; j < ft.NumField(); j++ {
+				nf := field.Field(j)
+				nsf := ft.Field(j)
+				if nf.Kind() == reflect.Func {
+					continue
+				}
+				sections.WriteString(fmt.Sprintf("%s = %s\n", nsf.Name, formatScalar(nf)))
+			}
+			continue
+		}
+		scalars.WriteString(fmt.Sprintf("%s = %s\n", sf.Name, formatScalar(field)))
+	}
+	return scalars.String() + sections.String()
+}
+
+// formatScalar renders a non-struct field's value as it would appear in
+// a YAML/TOML config file.
+func formatScalar(field reflect.Value) string {
+	switch {
+	case field.Type() == durationType:
+		return fmt.Sprintf("%q", time.Duration(field.Int()).String())
+	case field.Kind() == reflect.String:
+		return fmt.Sprintf("%q", field.String())
+	case field.Kind() == reflect.Int, field.Kind() == reflect.Int64:
+		return fmt.Sprintf("%d", field.Int())
+	case field.Kind() == reflect.Bool:
+		return fmt.Sprintf("%t", field.Bool())
+	case field.Kind() == reflect.Uint, field.Kind() == reflect.Uint8,
+		field.Kind() == reflect.Uint16, field.Kind() == reflect.Uint32,
+		field.Kind() == reflect.Uint64:
+		return fmt.Sprintf("%d", field.Uint())
+	default:
+		return ""
+	}
+}